@@ -0,0 +1,185 @@
+package main
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+//go:embed resources/dist
+var embeddedDist embed.FS
+
+//go:embed index.html
+var embeddedIndexHTML string
+
+// assetRefPattern matches href/src attributes in index.html that point at
+// a file under /resources/dist, so each can be fingerprinted.
+var assetRefPattern = regexp.MustCompile(`(href|src)="([^"]*?/resources/dist/([^"?]+))"`)
+
+// assetPipeline serves the SPA's static assets and index.html. In
+// production it's backed by the binary's embedded copy, fingerprinted
+// once at startup; in development it re-reads resources/dist and
+// index.html from disk on every request so edits show up without a
+// rebuild.
+type assetPipeline struct {
+	production bool
+	distFS     fs.FS
+
+	mu           sync.RWMutex
+	fingerprints map[string]string // path relative to resources/dist -> first 8 hex chars of its sha256
+	indexHTML    string            // index.html with ?v=<hash> applied to referenced assets
+}
+
+// newAssetPipeline builds the pipeline and computes its initial
+// fingerprints and index.html.
+func newAssetPipeline(production bool) (*assetPipeline, error) {
+	p := &assetPipeline{production: production}
+
+	if production {
+		distFS, err := fs.Sub(embeddedDist, "resources/dist")
+		if err != nil {
+			return nil, fmt.Errorf("assets: sub embedded resources/dist: %w", err)
+		}
+		p.distFS = distFS
+	} else {
+		p.distFS = os.DirFS("resources/dist")
+	}
+
+	if err := p.refresh(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// refresh recomputes fingerprints and index.html. In production this
+// only needs to run once, since the embedded assets never change; dev
+// mode calls it on every request, so the new values are built up in
+// local vars and only swapped into the struct under a write lock.
+func (p *assetPipeline) refresh() error {
+	fingerprints, err := fingerprintDist(p.distFS)
+	if err != nil {
+		return fmt.Errorf("assets: fingerprint resources/dist: %w", err)
+	}
+
+	var indexSource string
+	if p.production {
+		indexSource = embeddedIndexHTML
+	} else {
+		content, err := os.ReadFile(indexHTMLFile)
+		if err != nil {
+			return fmt.Errorf("assets: read index.html: %w", err)
+		}
+		indexSource = string(content)
+	}
+	indexHTML := rewriteAssetRefs(indexSource, fingerprints)
+
+	p.mu.Lock()
+	p.fingerprints = fingerprints
+	p.indexHTML = indexHTML
+	p.mu.Unlock()
+	return nil
+}
+
+// refreshIfDev re-reads assets from disk in development mode; it's a
+// no-op in production, where the embedded assets are fixed at build time.
+func (p *assetPipeline) refreshIfDev() error {
+	if p.production {
+		return nil
+	}
+	return p.refresh()
+}
+
+// IndexHTML returns the current rendering of index.html.
+func (p *assetPipeline) IndexHTML() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.indexHTML
+}
+
+// fingerprint returns the known fingerprint for a path relative to
+// resources/dist, if any.
+func (p *assetPipeline) fingerprint(relPath string) (string, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	hash, ok := p.fingerprints[relPath]
+	return hash, ok
+}
+
+// fingerprintDist walks distFS and computes a SHA-256 fingerprint for
+// every regular file in it.
+func fingerprintDist(distFS fs.FS) (map[string]string, error) {
+	fingerprints := make(map[string]string)
+
+	err := fs.WalkDir(distFS, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		f, err := distFS.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+
+		fingerprints[path] = hex.EncodeToString(h.Sum(nil))[:8]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fingerprints, nil
+}
+
+// rewriteAssetRefs appends "?v=<fingerprint>" to every /resources/dist
+// reference in html that has a known fingerprint.
+func rewriteAssetRefs(html string, fingerprints map[string]string) string {
+	return assetRefPattern.ReplaceAllStringFunc(html, func(match string) string {
+		groups := assetRefPattern.FindStringSubmatch(match)
+		attr, full, relPath := groups[1], groups[2], groups[3]
+
+		hash, ok := fingerprints[relPath]
+		if !ok {
+			return match
+		}
+		return fmt.Sprintf(`%s="%s?v=%s"`, attr, full, hash)
+	})
+}
+
+// handler serves GET {enclavePrefix}/resources/dist/*filepath with a
+// strong ETag and a long, immutable Cache-Control for any file whose
+// fingerprint we know, so browsers and reverse proxies can cache it
+// aggressively; the ?v= query string busts the cache on content change.
+func (p *assetPipeline) handler() http.HandlerFunc {
+	fileServer := http.FileServer(http.FS(p.distFS))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := p.refreshIfDev(); err != nil {
+			http.Error(w, "failed to load assets", http.StatusInternalServerError)
+			return
+		}
+
+		relPath := strings.TrimPrefix(r.URL.Path, fmt.Sprintf("%s/resources/dist/", enclavePrefix))
+		if hash, ok := p.fingerprint(relPath); ok {
+			w.Header().Set("ETag", fmt.Sprintf(`"%s"`, hash))
+			w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		}
+
+		http.StripPrefix(fmt.Sprintf("%s/resources/dist/", enclavePrefix), fileServer).ServeHTTP(w, r)
+	}
+}
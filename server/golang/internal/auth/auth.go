@@ -0,0 +1,141 @@
+// Package auth implements session-based authentication for the enclave UI
+// and API: interactive username/password(+TOTP) login with a signed
+// session cookie, "app passwords" for programmatic clients over HTTP
+// Basic auth, and a gin middleware that gates the protected routes.
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/sessions"
+	"github.com/pquerna/otp/totp"
+)
+
+// LoggedInKey is the gin context key under which the authenticated
+// username is stored once Middleware has run.
+const LoggedInKey = "loggedIn"
+
+const sessionUserKey = "username"
+
+// User is an interactive login account.
+type User struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	// TOTPSecret is the base32 TOTP seed for this user. Empty means the
+	// user has not enrolled in TOTP and only needs username+password.
+	TOTPSecret string `json:"totpSecret"`
+}
+
+// AppPassword is a username/password pair accepted via HTTP Basic auth,
+// intended for programmatic clients that can't complete an interactive
+// login.
+type AppPassword struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Config configures a Manager.
+type Config struct {
+	// SessionSecret signs and encrypts the session cookie. Required.
+	SessionSecret string
+	// Users are the interactive (username/password[/TOTP]) login accounts.
+	Users []User
+	// AppPasswords are accepted via HTTP Basic auth.
+	AppPasswords []AppPassword
+	// CookieName names the session cookie. Defaults to "enclave_session".
+	CookieName string
+	// LoginPath is where unauthenticated browser requests are redirected.
+	// Defaults to "/auth/login".
+	LoginPath string
+	// Secure marks the session cookie Secure, so browsers only send it
+	// over HTTPS. Should be true in production.
+	Secure bool
+}
+
+// Manager owns the session store and credential sources backing the
+// enclave's login subsystem.
+type Manager struct {
+	store        *sessions.CookieStore
+	cookieName   string
+	loginPath    string
+	users        map[string]User
+	appPasswords map[string]string
+}
+
+// New builds a Manager from cfg.
+func New(cfg Config) (*Manager, error) {
+	if cfg.SessionSecret == "" {
+		return nil, errors.New("auth: SessionSecret must not be empty")
+	}
+
+	cookieName := cfg.CookieName
+	if cookieName == "" {
+		cookieName = "enclave_session"
+	}
+	loginPath := cfg.LoginPath
+	if loginPath == "" {
+		loginPath = "/auth/login"
+	}
+
+	store := sessions.NewCookieStore([]byte(cfg.SessionSecret))
+	store.Options = &sessions.Options{
+		Path:     "/",
+		MaxAge:   int((24 * time.Hour).Seconds()),
+		HttpOnly: true,
+		Secure:   cfg.Secure,
+		SameSite: http.SameSiteLaxMode,
+	}
+
+	users := make(map[string]User, len(cfg.Users))
+	for _, u := range cfg.Users {
+		users[u.Username] = u
+	}
+	appPasswords := make(map[string]string, len(cfg.AppPasswords))
+	for _, p := range cfg.AppPasswords {
+		appPasswords[p.Username] = p.Password
+	}
+
+	return &Manager{
+		store:        store,
+		cookieName:   cookieName,
+		loginPath:    loginPath,
+		users:        users,
+		appPasswords: appPasswords,
+	}, nil
+}
+
+// CheckCredentials verifies username/password and, when the user has TOTP
+// enrolled, the passcode. passcode is ignored for users without a
+// TOTPSecret.
+func (m *Manager) CheckCredentials(username, password, passcode string) error {
+	user, ok := m.users[username]
+	if !ok || !constantTimeEqual(user.Password, password) {
+		return errors.New("auth: invalid username or password")
+	}
+	if user.TOTPSecret != "" {
+		if passcode == "" {
+			return errors.New("auth: passcode required")
+		}
+		if !totp.Validate(passcode, user.TOTPSecret) {
+			return errors.New("auth: invalid passcode")
+		}
+	}
+	return nil
+}
+
+// checkAppPassword verifies an HTTP Basic auth username/password pair
+// against the configured app passwords.
+func (m *Manager) checkAppPassword(username, password string) bool {
+	want, ok := m.appPasswords[username]
+	return ok && constantTimeEqual(want, password)
+}
+
+// constantTimeEqual reports whether a and b are equal, comparing in time
+// independent of where they first differ so secret comparisons don't leak
+// timing information.
+func constantTimeEqual(a, b string) bool {
+	return len(a) == len(b) && subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"encoding/base32"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pquerna/otp/totp"
+)
+
+func testSecret() string {
+	return base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+}
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	m, err := New(Config{
+		SessionSecret: "test-secret-test-secret-test-secret",
+		Users: []User{
+			{Username: "alice", Password: "hunter2"},
+			{Username: "bob", Password: "correcthorse", TOTPSecret: testSecret()},
+		},
+		AppPasswords: []AppPassword{
+			{Username: "ci-bot", Password: "app-pw"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return m
+}
+
+func TestCheckCredentials(t *testing.T) {
+	m := newTestManager(t)
+
+	if err := m.CheckCredentials("alice", "hunter2", ""); err != nil {
+		t.Errorf("expected alice to log in without TOTP, got %v", err)
+	}
+	if err := m.CheckCredentials("alice", "wrong", ""); err == nil {
+		t.Error("expected wrong password to be rejected")
+	}
+	if err := m.CheckCredentials("nobody", "whatever", ""); err == nil {
+		t.Error("expected unknown user to be rejected")
+	}
+
+	code, err := totp.GenerateCode(testSecret(), time.Now())
+	if err != nil {
+		t.Fatalf("GenerateCode() error = %v", err)
+	}
+	if err := m.CheckCredentials("bob", "correcthorse", ""); err == nil {
+		t.Error("expected bob to require a passcode")
+	}
+	if err := m.CheckCredentials("bob", "correcthorse", code); err != nil {
+		t.Errorf("expected bob to log in with a valid passcode, got %v", err)
+	}
+	if err := m.CheckCredentials("bob", "correcthorse", "000000"); err == nil {
+		t.Error("expected an invalid passcode to be rejected")
+	}
+}
+
+func TestLoginLogoutCookieRoundTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := newTestManager(t)
+
+	router := gin.New()
+	router.POST("/auth/login", m.LoginHandler)
+	router.POST("/auth/logout", m.LogoutHandler)
+	router.GET("/whoami", m.Middleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"loggedIn": c.GetString(LoggedInKey)})
+	})
+
+	// No session yet: protected route rejects.
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 before login, got %d", rec.Code)
+	}
+
+	// Log in and capture the session cookie.
+	loginRec := httptest.NewRecorder()
+	loginReq := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"username":"alice","password":"hunter2"}`))
+	loginReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(loginRec, loginReq)
+	if loginRec.Code != http.StatusOK {
+		t.Fatalf("expected 200 on login, got %d: %s", loginRec.Code, loginRec.Body.String())
+	}
+	cookies := loginRec.Result().Cookies()
+	if len(cookies) == 0 {
+		t.Fatal("expected a session cookie to be set")
+	}
+
+	// The cookie authenticates the protected route.
+	whoamiRec := httptest.NewRecorder()
+	whoamiReq := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	for _, ck := range cookies {
+		whoamiReq.AddCookie(ck)
+	}
+	router.ServeHTTP(whoamiRec, whoamiReq)
+	if whoamiRec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with session cookie, got %d", whoamiRec.Code)
+	}
+
+	// Logging out invalidates the cookie.
+	logoutRec := httptest.NewRecorder()
+	logoutReq := httptest.NewRequest(http.MethodPost, "/auth/logout", nil)
+	for _, ck := range cookies {
+		logoutReq.AddCookie(ck)
+	}
+	router.ServeHTTP(logoutRec, logoutReq)
+	if logoutRec.Code != http.StatusOK {
+		t.Fatalf("expected 200 on logout, got %d", logoutRec.Code)
+	}
+}
+
+func TestMiddlewareBasicAuthAppPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := newTestManager(t)
+
+	router := gin.New()
+	router.GET("/api/thing", m.Middleware(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"loggedIn": c.GetString(LoggedInKey)})
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/thing", nil)
+	req.SetBasicAuth("ci-bot", "app-pw")
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with a valid app password, got %d", rec.Code)
+	}
+
+	badRec := httptest.NewRecorder()
+	badReq := httptest.NewRequest(http.MethodGet, "/api/thing", nil)
+	badReq.SetBasicAuth("ci-bot", "wrong")
+	router.ServeHTTP(badRec, badReq)
+	if badRec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with an invalid app password, got %d", badRec.Code)
+	}
+}
+
+func TestSecureConfigMarksCookieSecure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m, err := New(Config{
+		SessionSecret: "test-secret-test-secret-test-secret",
+		Users:         []User{{Username: "alice", Password: "hunter2"}},
+		Secure:        true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	router := gin.New()
+	router.POST("/auth/login", m.LoginHandler)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/auth/login", strings.NewReader(`{"username":"alice","password":"hunter2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(rec, req)
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) == 0 {
+		t.Fatal("expected a session cookie to be set")
+	}
+	if !cookies[0].Secure {
+		t.Error("expected session cookie to be marked Secure")
+	}
+}
+
+func TestMiddlewareRedirectsBrowsers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := newTestManager(t)
+
+	router := gin.New()
+	router.GET("/ui", m.Middleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/ui", nil)
+	req.Header.Set("Accept", "text/html")
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusTemporaryRedirect {
+		t.Fatalf("expected 307 redirect for a browser request, got %d", rec.Code)
+	}
+}
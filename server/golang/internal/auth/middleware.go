@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware gates the enclave's /ui and /api routes. It accepts either a
+// valid session cookie (set by LoginHandler) or an HTTP Basic auth app
+// password. Browser requests (identified by an HTML Accept header) that
+// fail both are redirected to the login page; everything else gets a 401
+// JSON response.
+func (m *Manager) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if username, ok := m.basicAuthUsername(c.Request); ok {
+			c.Set(LoggedInKey, username)
+			c.Next()
+			return
+		}
+
+		if username, ok := m.sessionUsername(c.Request); ok {
+			c.Set(LoggedInKey, username)
+			c.Next()
+			return
+		}
+
+		if wantsHTML(c.Request) {
+			c.Redirect(http.StatusTemporaryRedirect, m.loginPath+"?redirect="+c.Request.URL.Path)
+			c.Abort()
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "authentication required"})
+	}
+}
+
+// basicAuthUsername checks the request's HTTP Basic auth credentials
+// against the configured app passwords.
+func (m *Manager) basicAuthUsername(r *http.Request) (string, bool) {
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return "", false
+	}
+	if !m.checkAppPassword(username, password) {
+		return "", false
+	}
+	return username, true
+}
+
+// wantsHTML reports whether the request looks like it came from a
+// browser navigation rather than a programmatic API call.
+func wantsHTML(r *http.Request) bool {
+	return strings.Contains(r.Header.Get("Accept"), "text/html")
+}
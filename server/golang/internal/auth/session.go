@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loginRequest is the JSON body accepted by LoginHandler.
+type loginRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Passcode string `json:"passcode"`
+}
+
+// LoginHandler verifies credentials and, on success, stores the username
+// in a signed session cookie.
+func (m *Manager) LoginHandler(c *gin.Context) {
+	var req loginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	if err := m.CheckCredentials(req.Username, req.Password, req.Passcode); err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	session, err := m.store.Get(c.Request, m.cookieName)
+	if err != nil {
+		// A corrupt/expired cookie on the incoming request: start fresh.
+		session, _ = m.store.New(c.Request, m.cookieName)
+	}
+	session.Values[sessionUserKey] = req.Username
+	if err := session.Save(c.Request, c.Writer); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to persist session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"username": req.Username})
+}
+
+// LogoutHandler clears the session cookie.
+func (m *Manager) LogoutHandler(c *gin.Context) {
+	session, err := m.store.Get(c.Request, m.cookieName)
+	if err == nil {
+		session.Options.MaxAge = -1
+		session.Save(c.Request, c.Writer)
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "logged out"})
+}
+
+// sessionUsername returns the username stored in the request's session
+// cookie, if any.
+func (m *Manager) sessionUsername(r *http.Request) (string, bool) {
+	session, err := m.store.Get(r, m.cookieName)
+	if err != nil {
+		return "", false
+	}
+	username, ok := session.Values[sessionUserKey].(string)
+	if !ok || username == "" {
+		return "", false
+	}
+	return username, true
+}
@@ -0,0 +1,123 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// ConnProvider supplies the outgoing context carrying the Scailo auth
+// token for an RPC call, so individual routes don't each need to know how
+// authentication is wired up.
+type ConnProvider interface {
+	Context(parent context.Context) context.Context
+}
+
+// Registry collects Routes and mounts them as gin handlers.
+type Registry struct {
+	conn   ConnProvider
+	routes []Route
+}
+
+// NewRegistry builds a Registry that authenticates outgoing RPCs via conn.
+func NewRegistry(conn ConnProvider) *Registry {
+	return &Registry{conn: conn}
+}
+
+// Register adds a Route to the registry.
+func (r *Registry) Register(route Route) {
+	r.routes = append(r.routes, route)
+}
+
+// Mount attaches every registered Route to group, e.g. a gin.RouterGroup
+// scoped to "/enclave/{name}/api".
+func (r *Registry) Mount(group gin.IRoutes) {
+	for _, route := range r.routes {
+		route := route
+		group.Handle(route.HTTPMethod, route.Path, r.handler(route))
+	}
+}
+
+func (r *Registry) handler(route Route) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req := route.NewRequest()
+		if c.Request.ContentLength != 0 {
+			body, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+				return
+			}
+			if err := protojson.Unmarshal(body, req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body: " + err.Error()})
+				return
+			}
+		}
+
+		ctx := r.conn.Context(c.Request.Context())
+
+		if route.Stream != nil {
+			r.serveStream(c, ctx, route, req)
+			return
+		}
+
+		resp, err := route.Invoke(ctx, req)
+		if err != nil {
+			c.JSON(statusToHTTP(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		body, err := protojson.Marshal(resp)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to marshal response"})
+			return
+		}
+		c.Data(http.StatusOK, "application/json", body)
+	}
+}
+
+// serveStream upgrades a server-streaming RPC to Server-Sent Events, one
+// "data:" frame per message received from the Scailo API.
+func (r *Registry) serveStream(c *gin.Context, ctx context.Context, route Route, req proto.Message) {
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	flusher, canFlush := c.Writer.(http.Flusher)
+
+	err := route.Stream(ctx, req, func(msg proto.Message) error {
+		body, err := protojson.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		if _, err := c.Writer.Write(append(append([]byte("data: "), body...), '\n', '\n')); err != nil {
+			return err
+		}
+		if canFlush {
+			flusher.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// Headers are already sent; best effort error reporting as an
+		// SSE comment rather than changing the status code. Strip
+		// newlines so a multi-line error can't inject extra SSE
+		// fields or terminate the frame early.
+		c.Writer.Write([]byte("event: error\ndata: " + sanitizeSSEText(err.Error()) + "\n\n"))
+		if canFlush {
+			flusher.Flush()
+		}
+	}
+}
+
+// sanitizeSSEText collapses newlines in s so it can be embedded in a
+// single SSE "data:" line without truncating or splitting the frame.
+func sanitizeSSEText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", " ")
+	s = strings.ReplaceAll(s, "\n", " ")
+	return strings.ReplaceAll(s, "\r", " ")
+}
@@ -0,0 +1,150 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type fakeConn struct{}
+
+func (fakeConn) Context(parent context.Context) context.Context { return parent }
+
+func TestRegistryMountInvoke(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry := NewRegistry(fakeConn{})
+	registry.Register(Route{
+		HTTPMethod: http.MethodPost,
+		Path:       "/v1/ping",
+		NewRequest: func() proto.Message { return &emptypb.Empty{} },
+		Invoke: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return &emptypb.Empty{}, nil
+		},
+	})
+
+	router := gin.New()
+	registry.Mount(router)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/ping", strings.NewReader("{}"))
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRegistryMapsGRPCErrorsToHTTPStatus(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry := NewRegistry(fakeConn{})
+	registry.Register(Route{
+		HTTPMethod: http.MethodPost,
+		Path:       "/v1/notfound",
+		NewRequest: func() proto.Message { return &emptypb.Empty{} },
+		Invoke: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return nil, status.Error(codes.NotFound, "no such thing")
+		},
+	})
+
+	router := gin.New()
+	registry.Mount(router)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/notfound", strings.NewReader("{}"))
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404, got %d", rec.Code)
+	}
+}
+
+func TestRegistryMountStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry := NewRegistry(fakeConn{})
+	registry.Register(Route{
+		HTTPMethod: http.MethodPost,
+		Path:       "/v1/ping/stream",
+		NewRequest: func() proto.Message { return &emptypb.Empty{} },
+		Stream: func(ctx context.Context, req proto.Message, send func(proto.Message) error) error {
+			for i := 0; i < 3; i++ {
+				if err := send(&emptypb.Empty{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	})
+
+	router := gin.New()
+	registry.Mount(router)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/ping/stream", strings.NewReader("{}"))
+	router.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got %q", ct)
+	}
+	if got := strings.Count(rec.Body.String(), "data: "); got != 3 {
+		t.Errorf("expected 3 SSE frames, got %d: %s", got, rec.Body.String())
+	}
+}
+
+func TestRegistryStreamErrorSanitizesNewlines(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry := NewRegistry(fakeConn{})
+	registry.Register(Route{
+		HTTPMethod: http.MethodPost,
+		Path:       "/v1/broken/stream",
+		NewRequest: func() proto.Message { return &emptypb.Empty{} },
+		Stream: func(ctx context.Context, req proto.Message, send func(proto.Message) error) error {
+			return status.Error(codes.Internal, "line one\nline two\r\nline three")
+		},
+	})
+
+	router := gin.New()
+	registry.Mount(router)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/broken/stream", strings.NewReader("{}"))
+	router.ServeHTTP(rec, req)
+
+	body := rec.Body.String()
+	lines := strings.Split(strings.TrimRight(body, "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected a single event/data line pair, got %d lines: %q", len(lines), body)
+	}
+	if !strings.HasPrefix(lines[1], "data: ") || strings.Contains(lines[1][len("data: "):], "\n") {
+		t.Errorf("expected a single sanitized data: line, got %q", lines[1])
+	}
+}
+
+func TestStatusToHTTP(t *testing.T) {
+	cases := map[error]int{
+		status.Error(codes.InvalidArgument, "x"):  http.StatusBadRequest,
+		status.Error(codes.Unauthenticated, "x"):  http.StatusUnauthorized,
+		status.Error(codes.PermissionDenied, "x"): http.StatusForbidden,
+		status.Error(codes.Unavailable, "x"):      http.StatusServiceUnavailable,
+		status.Error(codes.DeadlineExceeded, "x"): http.StatusGatewayTimeout,
+	}
+	for err, want := range cases {
+		if got := statusToHTTP(err); got != want {
+			t.Errorf("statusToHTTP(%v) = %d, want %d", err, got, want)
+		}
+	}
+}
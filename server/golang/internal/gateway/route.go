@@ -0,0 +1,35 @@
+// Package gateway hand-rolls an HTTP/JSON bridge onto the Scailo gRPC
+// surface: each Route describes how to unmarshal a JSON request body into
+// a protobuf request message, invoke an RPC over the enclave's shared
+// connection, and marshal the response back with protojson. There is no
+// grpc-gateway codegen involved; routes are registered by hand for
+// whichever RPCs the enclave wants to expose to the browser.
+package gateway
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Route describes a single HTTP endpoint backed by a gRPC method. Exactly
+// one of Invoke or Stream should be set.
+type Route struct {
+	// HTTPMethod is the HTTP verb this route responds to, e.g. "POST".
+	HTTPMethod string
+	// Path is the gin route pattern, relative to the gateway's mount
+	// point, e.g. "/v1/users/ViewByID".
+	Path string
+	// NewRequest constructs a zero-value request message to unmarshal
+	// the JSON body into.
+	NewRequest func() proto.Message
+	// Invoke calls a unary RPC method.
+	Invoke func(ctx context.Context, req proto.Message) (proto.Message, error)
+	// Stream calls a server-streaming RPC method, invoking send once
+	// per message received. When set, Invoke is ignored and the
+	// response is written to the client as Server-Sent Events instead
+	// of a single JSON body. No Scailo RPC the enclave currently
+	// exposes is server-streaming, so this has no registered user yet;
+	// it exists so one can be wired up without another gateway change.
+	Stream func(ctx context.Context, req proto.Message, send func(proto.Message) error) error
+}
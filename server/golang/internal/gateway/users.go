@@ -0,0 +1,35 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/scailo/go-sdk"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// RegisterUsersService adds the Users service RPCs the enclave exposes to
+// the browser under /v1/users/{method}. cc is the enclave's shared,
+// authenticated connection to the Scailo API.
+func (r *Registry) RegisterUsersService(cc grpc.ClientConnInterface) {
+	client := sdk.NewUsersServiceClient(cc)
+
+	r.Register(Route{
+		HTTPMethod: http.MethodPost,
+		Path:       "/v1/users/ViewByID",
+		NewRequest: func() proto.Message { return &sdk.IdentifierZeroable{} },
+		Invoke: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return client.ViewByID(ctx, req.(*sdk.IdentifierZeroable))
+		},
+	})
+
+	r.Register(Route{
+		HTTPMethod: http.MethodPost,
+		Path:       "/v1/users/ViewAll",
+		NewRequest: func() proto.Message { return &sdk.ActiveStatus{} },
+		Invoke: func(ctx context.Context, req proto.Message) (proto.Message, error) {
+			return client.ViewAll(ctx, req.(*sdk.ActiveStatus))
+		},
+	})
+}
@@ -0,0 +1,46 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+)
+
+// noopConn satisfies grpc.ClientConnInterface without dialing anywhere;
+// RegisterUsersService only needs it to construct a client, not to call it.
+type noopConn struct{}
+
+func (noopConn) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...grpc.CallOption) error {
+	panic("not implemented")
+}
+
+func (noopConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	panic("not implemented")
+}
+
+func TestRegisterUsersServiceMountsExpectedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	registry := NewRegistry(fakeConn{})
+	registry.RegisterUsersService(noopConn{})
+
+	router := gin.New()
+	registry.Mount(router)
+
+	want := map[string]bool{
+		"/v1/users/ViewByID": false,
+		"/v1/users/ViewAll":  false,
+	}
+	for _, route := range router.Routes() {
+		if _, ok := want[route.Path]; ok {
+			want[route.Path] = true
+		}
+	}
+	for path, found := range want {
+		if !found {
+			t.Errorf("expected route %s to be mounted", path)
+		}
+	}
+}
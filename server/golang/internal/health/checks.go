@@ -0,0 +1,85 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc/connectivity"
+)
+
+// LoginState is the subset of scailoauth.TokenManager the "scailo-login"
+// checker needs.
+type LoginState interface {
+	LastRefresh() time.Time
+}
+
+type loginChecker struct {
+	state  LoginState
+	maxAge time.Duration
+}
+
+// NewLoginChecker reports healthy once state has refreshed at least once
+// and the refresh isn't older than maxAge (by convention, twice the
+// configured login interval).
+func NewLoginChecker(state LoginState, maxAge time.Duration) Checker {
+	return &loginChecker{state: state, maxAge: maxAge}
+}
+
+func (c *loginChecker) Name() string { return "scailo-login" }
+
+func (c *loginChecker) Check(ctx context.Context) error {
+	last := c.state.LastRefresh()
+	if last.IsZero() {
+		return errors.New("no successful login yet")
+	}
+	if age := time.Since(last); age > c.maxAge {
+		return fmt.Errorf("last login was %s ago, exceeding %s", age.Round(time.Second), c.maxAge)
+	}
+	return nil
+}
+
+// ConnStater is the subset of *grpc.ClientConn the "scailo-grpc" checker
+// needs.
+type ConnStater interface {
+	GetState() connectivity.State
+}
+
+type grpcChecker struct {
+	conn ConnStater
+}
+
+// NewGRPCChecker reports healthy when conn is READY or IDLE (IDLE means
+// no RPC has needed the connection yet, not that it's broken).
+func NewGRPCChecker(conn ConnStater) Checker {
+	return &grpcChecker{conn: conn}
+}
+
+func (c *grpcChecker) Name() string { return "scailo-grpc" }
+
+func (c *grpcChecker) Check(ctx context.Context) error {
+	switch state := c.conn.GetState(); state {
+	case connectivity.Ready, connectivity.Idle:
+		return nil
+	default:
+		return fmt.Errorf("connection state is %s", state)
+	}
+}
+
+type indexHTMLChecker struct {
+	read func() error
+}
+
+// NewIndexHTMLChecker reports healthy when read succeeds; callers supply
+// whatever "can we load index.html" means for their asset pipeline
+// (reading embedded bytes in production, the file on disk in dev).
+func NewIndexHTMLChecker(read func() error) Checker {
+	return &indexHTMLChecker{read: read}
+}
+
+func (c *indexHTMLChecker) Name() string { return "index-html-readable" }
+
+func (c *indexHTMLChecker) Check(ctx context.Context) error {
+	return c.read()
+}
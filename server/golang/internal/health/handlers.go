@@ -0,0 +1,49 @@
+package health
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LivelinessHandler stays cheap: it never runs the registered checks, it
+// just confirms the process is up and able to respond.
+func LivelinessHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "OK"})
+}
+
+// ReadinessHandler returns 200 with a per-check report when every check
+// passes, or 503 with the same report when any check fails.
+func (r *Registry) ReadinessHandler(c *gin.Context) {
+	ok, reports := r.Check(c.Request.Context())
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, gin.H{"checks": reports})
+}
+
+// StartupHandler blocks until every check passes for the first time (or
+// the request's context is canceled, e.g. by the orchestrator's own
+// startup-probe timeout), then responds 200.
+func (r *Registry) StartupHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		if ok, reports := r.Check(ctx); ok {
+			c.JSON(http.StatusOK, gin.H{"checks": reports})
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "not ready"})
+			return
+		case <-ticker.C:
+		}
+	}
+}
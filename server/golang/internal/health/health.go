@@ -0,0 +1,49 @@
+// Package health implements the enclave's health-check subsystem: a
+// small Checker interface, concrete checks for the things that actually
+// determine whether the enclave can serve traffic, and a Registry that
+// aggregates them into the /health endpoints.
+package health
+
+import "context"
+
+// Checker is a single health check.
+type Checker interface {
+	// Name identifies the check in the readiness report.
+	Name() string
+	// Check returns an error describing why the check is failing, or
+	// nil if it's healthy.
+	Check(ctx context.Context) error
+}
+
+// Report is one Checker's result.
+type Report struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Registry aggregates Checkers and reports their combined status.
+type Registry struct {
+	checkers []Checker
+}
+
+// NewRegistry builds a Registry from the given checkers.
+func NewRegistry(checkers ...Checker) *Registry {
+	return &Registry{checkers: checkers}
+}
+
+// Check runs every registered Checker and reports whether all of them
+// passed, alongside a per-check Report.
+func (r *Registry) Check(ctx context.Context) (ok bool, reports []Report) {
+	ok = true
+	for _, c := range r.checkers {
+		report := Report{Name: c.Name(), Status: "OK"}
+		if err := c.Check(ctx); err != nil {
+			report.Status = "FAIL"
+			report.Error = err.Error()
+			ok = false
+		}
+		reports = append(reports, report)
+	}
+	return ok, reports
+}
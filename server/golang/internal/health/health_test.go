@@ -0,0 +1,130 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/connectivity"
+)
+
+type fakeChecker struct {
+	name string
+	err  error
+}
+
+func (f fakeChecker) Name() string                    { return f.name }
+func (f fakeChecker) Check(ctx context.Context) error { return f.err }
+
+func TestRegistryCheckAggregatesResults(t *testing.T) {
+	reg := NewRegistry(fakeChecker{name: "ok"}, fakeChecker{name: "bad", err: errors.New("boom")})
+
+	ok, reports := reg.Check(context.Background())
+	if ok {
+		t.Error("expected ok=false when any checker fails")
+	}
+	if len(reports) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(reports))
+	}
+	if reports[0].Status != "OK" || reports[1].Status != "FAIL" || reports[1].Error != "boom" {
+		t.Errorf("unexpected reports: %+v", reports)
+	}
+}
+
+type fakeLoginState struct{ last time.Time }
+
+func (f fakeLoginState) LastRefresh() time.Time { return f.last }
+
+func TestLoginChecker(t *testing.T) {
+	checker := NewLoginChecker(fakeLoginState{}, time.Hour)
+	if err := checker.Check(context.Background()); err == nil {
+		t.Error("expected an error before the first login")
+	}
+
+	checker = NewLoginChecker(fakeLoginState{last: time.Now()}, time.Hour)
+	if err := checker.Check(context.Background()); err != nil {
+		t.Errorf("expected a fresh login to pass, got %v", err)
+	}
+
+	checker = NewLoginChecker(fakeLoginState{last: time.Now().Add(-2 * time.Hour)}, time.Hour)
+	if err := checker.Check(context.Background()); err == nil {
+		t.Error("expected a stale login to fail")
+	}
+}
+
+type fakeConnStater struct{ state connectivity.State }
+
+func (f fakeConnStater) GetState() connectivity.State { return f.state }
+
+func TestGRPCChecker(t *testing.T) {
+	if err := NewGRPCChecker(fakeConnStater{state: connectivity.Ready}).Check(context.Background()); err != nil {
+		t.Errorf("expected Ready to pass, got %v", err)
+	}
+	if err := NewGRPCChecker(fakeConnStater{state: connectivity.TransientFailure}).Check(context.Background()); err == nil {
+		t.Error("expected TransientFailure to fail")
+	}
+}
+
+func TestReadinessHandlerReturns503WhenAnyCheckFails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	reg := NewRegistry(fakeChecker{name: "bad", err: errors.New("down")})
+
+	router := gin.New()
+	router.GET("/readiness", reg.ReadinessHandler)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readiness", nil))
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("expected 503, got %d", rec.Code)
+	}
+}
+
+func TestStartupHandlerSucceedsOnceChecksPass(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	state := &syncedLoginState{}
+	reg := NewRegistry(NewLoginChecker(state, time.Hour))
+
+	router := gin.New()
+	router.GET("/startup", reg.StartupHandler)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		state.setLastRefresh(time.Now())
+	}()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/startup", nil)
+	ctx, cancel := context.WithTimeout(req.Context(), 2*time.Second)
+	defer cancel()
+	router.ServeHTTP(rec, req.WithContext(ctx))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 once the check passes, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+// syncedLoginState is like fakeLoginState but, mirroring TokenManager's own
+// RWMutex-guarded access, safe for the test goroutine to mutate while
+// StartupHandler's polling loop concurrently reads it.
+type syncedLoginState struct {
+	mu   sync.RWMutex
+	last time.Time
+}
+
+func (s *syncedLoginState) setLastRefresh(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = t
+}
+
+func (s *syncedLoginState) LastRefresh() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.last
+}
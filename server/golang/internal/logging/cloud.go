@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	cloudlogging "cloud.google.com/go/logging"
+)
+
+// newCloudWriter returns an io.Writer that ships each line it's given to
+// Cloud Logging as a single structured entry under logName, inferring the
+// severity from the slog JSON payload's "level" field.
+func newCloudWriter(projectID, logName string) (*cloudLogWriter, func() error, error) {
+	client, err := cloudlogging.NewClient(context.Background(), projectID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("logging: create cloud logging client: %w", err)
+	}
+	return &cloudLogWriter{logger: client.Logger(logName)}, client.Close, nil
+}
+
+type cloudLogWriter struct {
+	logger *cloudlogging.Logger
+}
+
+func (w *cloudLogWriter) Write(p []byte) (int, error) {
+	line := append([]byte(nil), p...)
+	w.logger.Log(cloudlogging.Entry{
+		Payload:  json.RawMessage(line),
+		Severity: severityFromJSON(line),
+	})
+	return len(p), nil
+}
+
+func severityFromJSON(line []byte) cloudlogging.Severity {
+	switch {
+	case bytes.Contains(line, []byte(`"level":"ERROR"`)):
+		return cloudlogging.Error
+	case bytes.Contains(line, []byte(`"level":"WARN"`)):
+		return cloudlogging.Warning
+	case bytes.Contains(line, []byte(`"level":"DEBUG"`)):
+		return cloudlogging.Debug
+	default:
+		return cloudlogging.Info
+	}
+}
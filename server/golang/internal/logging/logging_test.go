@@ -0,0 +1,39 @@
+package logging
+
+import (
+	"log/slog"
+	"testing"
+
+	cloudlogging "cloud.google.com/go/logging"
+)
+
+func TestParseLevel(t *testing.T) {
+	cases := map[string]slog.Level{
+		"":        slog.LevelInfo,
+		"info":    slog.LevelInfo,
+		"debug":   slog.LevelDebug,
+		"warn":    slog.LevelWarn,
+		"warning": slog.LevelWarn,
+		"ERROR":   slog.LevelError,
+		"bogus":   slog.LevelInfo,
+	}
+	for input, want := range cases {
+		if got := parseLevel(input); got != want {
+			t.Errorf("parseLevel(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestSeverityFromJSON(t *testing.T) {
+	cases := map[string]cloudlogging.Severity{
+		`{"level":"ERROR","msg":"boom"}`: cloudlogging.Error,
+		`{"level":"WARN","msg":"hm"}`:    cloudlogging.Warning,
+		`{"level":"DEBUG","msg":"x"}`:    cloudlogging.Debug,
+		`{"level":"INFO","msg":"ok"}`:    cloudlogging.Info,
+	}
+	for input, want := range cases {
+		if got := severityFromJSON([]byte(input)); got != want {
+			t.Errorf("severityFromJSON(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
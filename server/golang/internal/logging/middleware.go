@@ -0,0 +1,45 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type contextKey struct{}
+
+var loggerContextKey = contextKey{}
+
+// Middleware assigns each request a UUID, logs method/path/status/latency
+// once the request completes, and stashes a request-scoped logger (with
+// the request ID attached) in the request context so handlers can call
+// FromContext.
+func Middleware(base *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		reqLogger := base.With("request_id", uuid.NewString())
+
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), loggerContextKey, reqLogger))
+
+		c.Next()
+
+		reqLogger.Info("request",
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"status", c.Writer.Status(),
+			"latency_ms", time.Since(start).Milliseconds(),
+		)
+	}
+}
+
+// FromContext returns the request-scoped logger stashed by Middleware, or
+// slog.Default() if none is present (e.g. outside a request).
+func FromContext(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerContextKey).(*slog.Logger); ok {
+		return l
+	}
+	return slog.Default()
+}
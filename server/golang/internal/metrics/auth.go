@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireBearerToken gates a route behind a static bearer token, for
+// deployments that want to scrape /metrics without going through the
+// enclave's interactive auth middleware.
+func RequireBearerToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		got := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if got == "" || !constantTimeEqual(got, token) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid or missing metrics token"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// constantTimeEqual reports whether a and b are equal, comparing in time
+// independent of where they first differ so the token comparison doesn't
+// leak timing information.
+func constantTimeEqual(a, b string) bool {
+	return len(a) == len(b) && subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
@@ -0,0 +1,69 @@
+// Package metrics wires prometheus/client_golang into the enclave: the
+// default Go/process collectors (registered by client_golang itself on
+// import), a gin middleware recording per-route request counts and
+// latency, and a scailo_login_total{result} counter the login subsystem
+// increments.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "enclave_http_requests_total",
+		Help: "Total HTTP requests handled, by route, method, and status.",
+	}, []string{"route", "method", "status"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "enclave_http_request_duration_seconds",
+		Help:    "HTTP request latency in seconds, by route and method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"route", "method"})
+
+	// LoginTotal is incremented by the Scailo login subsystem, one per
+	// attempt, labeled "success" or "failure".
+	LoginTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "scailo_login_total",
+		Help: "Total Scailo login attempts, by result.",
+	}, []string{"result"})
+)
+
+func init() {
+	// client_golang's own package init() already registers a GoCollector
+	// and ProcessCollector on prometheus.DefaultRegisterer; only our own
+	// collectors need registering here.
+	prometheus.MustRegister(
+		requestsTotal,
+		requestDuration,
+		LoginTotal,
+	)
+}
+
+// Middleware records a request count and latency observation per route
+// and method once the request completes.
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		requestsTotal.WithLabelValues(route, c.Request.Method, strconv.Itoa(c.Writer.Status())).Inc()
+		requestDuration.WithLabelValues(route, c.Request.Method).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler exposes every registered collector for scraping.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequireBearerToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/metrics", RequireBearerToken("s3cret"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200 with a valid token, got %d", rec.Code)
+	}
+
+	badRec := httptest.NewRecorder()
+	badReq := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	badReq.Header.Set("Authorization", "Bearer wrong")
+	router.ServeHTTP(badRec, badReq)
+	if badRec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with an invalid token, got %d", badRec.Code)
+	}
+
+	noAuthRec := httptest.NewRecorder()
+	router.ServeHTTP(noAuthRec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if noAuthRec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 with no token, got %d", noAuthRec.Code)
+	}
+}
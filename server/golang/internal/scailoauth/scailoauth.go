@@ -0,0 +1,226 @@
+// Package scailoauth manages the enclave's service-account login against
+// the Scailo API: a long-lived gRPC connection, proactive token refresh,
+// and exponential backoff on transient login failures instead of the
+// panic/Fatalf behavior of the original ad-hoc login goroutine.
+package scailoauth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/scailo/go-sdk"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/scailo/create-enclave/server/golang/internal/metrics"
+)
+
+// loginClient is the subset of sdk.LoginServiceClient the manager needs.
+// It's defined as an interface so tests can substitute a fake without
+// dialing a real connection.
+type loginClient interface {
+	LoginAsEmployeePrimary(ctx context.Context, req *sdk.UserLoginRequest, opts ...grpc.CallOption) (*sdk.UserLoginResponse, error)
+}
+
+// Config configures a TokenManager.
+type Config struct {
+	// ServerAddr is the Scailo API address, e.g. "https://api.example.com".
+	ServerAddr string
+	Username   string
+	Password   string
+	// TokenLifetime is how long an issued auth token remains valid.
+	// Defaults to 1 hour.
+	TokenLifetime time.Duration
+	// RefreshFraction controls how early to refresh relative to
+	// TokenLifetime, e.g. 0.8 refreshes at 80% of the lifetime.
+	// Defaults to 0.8.
+	RefreshFraction float64
+	// Logger receives login attempt/success/failure events. Defaults to
+	// slog.Default().
+	Logger *slog.Logger
+}
+
+// TokenManager owns a long-lived connection to the Scailo API and keeps
+// its login token fresh in the background via Run.
+type TokenManager struct {
+	conn   *grpc.ClientConn
+	client loginClient
+
+	username string
+	password string
+	lifetime time.Duration
+	fraction float64
+	logger   *slog.Logger
+
+	mu          sync.RWMutex
+	token       string
+	lastRefresh time.Time
+}
+
+// New dials the Scailo API and returns a TokenManager. Call Run to start
+// the background refresh loop.
+func New(cfg Config) (*TokenManager, error) {
+	lifetime := cfg.TokenLifetime
+	if lifetime == 0 {
+		lifetime = time.Hour
+	}
+	fraction := cfg.RefreshFraction
+	if fraction == 0 {
+		fraction = 0.8
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	var creds grpc.DialOption
+	if strings.HasPrefix(cfg.ServerAddr, "http://") {
+		creds = grpc.WithTransportCredentials(insecure.NewCredentials())
+	} else {
+		creds = grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, serverHost(cfg.ServerAddr)))
+	}
+
+	conn, err := grpc.NewClient(serverHost(cfg.ServerAddr),
+		creds,
+		grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:                30 * time.Second,
+			Timeout:             10 * time.Second,
+			PermitWithoutStream: true,
+		}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("scailoauth: dial %s: %w", cfg.ServerAddr, err)
+	}
+
+	return &TokenManager{
+		conn:     conn,
+		client:   sdk.NewLoginServiceClient(conn),
+		username: cfg.Username,
+		password: cfg.Password,
+		lifetime: lifetime,
+		fraction: fraction,
+		logger:   logger,
+	}, nil
+}
+
+// serverHost strips a scheme prefix from addr, since grpc.NewClient wants
+// a bare host:port target.
+func serverHost(addr string) string {
+	if strings.Contains(addr, "//") {
+		parts := strings.SplitN(addr, "//", 2)
+		if len(parts) > 1 {
+			return parts[1]
+		}
+	}
+	return addr
+}
+
+// Run logs in and then keeps the token refreshed until ctx is canceled,
+// returning ctx.Err() at that point. It should be run in its own
+// goroutine.
+func (m *TokenManager) Run(ctx context.Context) error {
+	for {
+		if err := m.login(ctx); err != nil {
+			return err
+		}
+
+		wait := time.Duration(float64(m.lifetime) * m.fraction)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// log returns the configured logger, falling back to slog.Default() for
+// TokenManager values built directly (as tests do) rather than via New.
+func (m *TokenManager) log() *slog.Logger {
+	if m.logger != nil {
+		return m.logger
+	}
+	return slog.Default()
+}
+
+// login performs a single login, retrying transient failures with
+// unbounded exponential backoff until it succeeds or ctx is canceled.
+func (m *TokenManager) login(ctx context.Context) error {
+	bo := backoff.NewExponentialBackOff()
+	bo.InitialInterval = 10 * time.Millisecond
+	bo.MaxInterval = 10 * time.Second
+	bo.MaxElapsedTime = 0 // retry forever; only ctx cancellation gives up
+
+	attempt := 0
+	err := backoff.Retry(func() error {
+		attempt++
+		resp, err := m.client.LoginAsEmployeePrimary(ctx, &sdk.UserLoginRequest{
+			Username:          m.username,
+			PlainTextPassword: m.password,
+		})
+		if err != nil {
+			m.log().Warn("scailo login attempt failed", "attempt", attempt, "error", err)
+			return err
+		}
+
+		m.mu.Lock()
+		m.token = resp.AuthToken
+		m.lastRefresh = time.Now()
+		m.mu.Unlock()
+		return nil
+	}, backoff.WithContext(bo, ctx))
+
+	if err != nil {
+		metrics.LoginTotal.WithLabelValues("failure").Inc()
+		m.log().Error("scailo login failed permanently", "attempts", attempt, "error", err)
+		return err
+	}
+	metrics.LoginTotal.WithLabelValues("success").Inc()
+	m.log().Info("scailo login succeeded", "attempts", attempt)
+	return nil
+}
+
+// Token returns the current auth token, or "" if no login has succeeded
+// yet.
+func (m *TokenManager) Token() string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.token
+}
+
+// LastRefresh returns when the token was last successfully refreshed.
+func (m *TokenManager) LastRefresh() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastRefresh
+}
+
+// Context returns parent with the current auth token attached as
+// outgoing gRPC metadata, matching what every Scailo API call needs.
+// Callers needing the shared connection (e.g. the gateway) use this
+// instead of touching package globals.
+func (m *TokenManager) Context(parent context.Context) context.Context {
+	token := m.Token()
+	if token == "" {
+		return parent
+	}
+	return metadata.NewOutgoingContext(parent, metadata.Pairs("auth_token", token))
+}
+
+// Conn returns the shared gRPC connection, e.g. for health checks that
+// inspect connection state.
+func (m *TokenManager) Conn() *grpc.ClientConn {
+	return m.conn
+}
+
+// Close releases the underlying connection.
+func (m *TokenManager) Close() error {
+	return m.conn.Close()
+}
@@ -0,0 +1,104 @@
+package scailoauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/scailo/go-sdk"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeLoginClient fails its first failN calls, then succeeds.
+type fakeLoginClient struct {
+	mu    sync.Mutex
+	calls int
+	failN int
+}
+
+func (f *fakeLoginClient) LoginAsEmployeePrimary(ctx context.Context, req *sdk.UserLoginRequest, opts ...grpc.CallOption) (*sdk.UserLoginResponse, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.calls <= f.failN {
+		return nil, errors.New("transient failure")
+	}
+	return &sdk.UserLoginResponse{AuthToken: fmt.Sprintf("token-%d", f.calls)}, nil
+}
+
+func TestLoginRetriesTransientFailures(t *testing.T) {
+	client := &fakeLoginClient{failN: 2}
+	m := &TokenManager{client: client, username: "u", password: "p", lifetime: time.Hour, fraction: 0.8}
+
+	if err := m.login(context.Background()); err != nil {
+		t.Fatalf("login() error = %v", err)
+	}
+	if got, want := m.Token(), "token-3"; got != want {
+		t.Errorf("Token() = %q, want %q", got, want)
+	}
+	if client.calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", client.calls)
+	}
+	if m.LastRefresh().IsZero() {
+		t.Error("expected LastRefresh to be set after a successful login")
+	}
+}
+
+func TestLoginGivesUpWhenContextCanceled(t *testing.T) {
+	client := &fakeLoginClient{failN: 1000}
+	m := &TokenManager{client: client, username: "u", password: "p", lifetime: time.Hour, fraction: 0.8}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := m.login(ctx); err == nil {
+		t.Error("expected login to return an error once the context is canceled")
+	}
+}
+
+func TestContextAttachesToken(t *testing.T) {
+	m := &TokenManager{}
+	m.token = "abc"
+
+	ctx := m.Context(context.Background())
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		t.Fatal("expected outgoing metadata to be attached")
+	}
+	if got := md.Get("auth_token"); len(got) != 1 || got[0] != "abc" {
+		t.Errorf("auth_token = %v, want [abc]", got)
+	}
+}
+
+func TestContextWithoutTokenReturnsParentUnchanged(t *testing.T) {
+	m := &TokenManager{}
+	parent := context.Background()
+
+	if got := m.Context(parent); got != parent {
+		t.Error("expected the parent context to be returned unchanged when no token is set")
+	}
+}
+
+func TestRunStopsOnContextCancel(t *testing.T) {
+	client := &fakeLoginClient{}
+	m := &TokenManager{client: client, username: "u", password: "p", lifetime: time.Hour, fraction: 0.8}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.Run(ctx) }()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("expected Run to return an error once the context is canceled")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return after context cancellation")
+	}
+}
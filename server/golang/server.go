@@ -2,26 +2,29 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
+	"log/slog"
 	"math/rand"
 	"net/http"
 	"os"
-	"path/filepath"
+	"os/signal"
 	"strconv"
-	"strings"
-	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/scailo/go-sdk"
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials"
-	"google.golang.org/grpc/credentials/insecure"
-	"google.golang.org/grpc/metadata"
 
 	"github.com/joho/godotenv"
 	_ "github.com/joho/godotenv/autoload"
+
+	"github.com/scailo/create-enclave/server/golang/internal/auth"
+	"github.com/scailo/create-enclave/server/golang/internal/gateway"
+	"github.com/scailo/create-enclave/server/golang/internal/health"
+	"github.com/scailo/create-enclave/server/golang/internal/logging"
+	"github.com/scailo/create-enclave/server/golang/internal/metrics"
+	"github.com/scailo/create-enclave/server/golang/internal/scailoauth"
 )
 
 // Config holds all necessary environment variables
@@ -31,19 +34,25 @@ type Config struct {
 	Port        int
 	Username    string
 	Password    string
+
+	// SessionSecret signs the login session cookie.
+	SessionSecret string
+	// Users are the interactive login accounts for the enclave UI.
+	Users []auth.User
+	// AppPasswords are accepted via HTTP Basic auth for programmatic clients.
+	AppPasswords []auth.AppPassword
 }
 
 // Global state variables
 var (
-	GlobalConfig Config
-	AuthToken    string
-	// Use this context for all Scailo API calls
-	ScailoAPICtx context.Context
-	// Mutex to protect shared state
-	mu            sync.RWMutex
-	production    bool   = false
-	indexPage     string // Cached version of index.html
+	GlobalConfig  Config
+	production    bool = false
 	enclavePrefix string
+	authManager   *auth.Manager
+	tokenManager  *scailoauth.TokenManager
+	logger        *slog.Logger
+	loggerCloser  func() error
+	siteAssets    *assetPipeline
 )
 
 const (
@@ -76,6 +85,29 @@ func loadConfig() {
 	GlobalConfig.ScailoAPI = os.Getenv("SCAILO_API")
 	GlobalConfig.Username = os.Getenv("USERNAME")
 	GlobalConfig.Password = os.Getenv("PASSWORD")
+	GlobalConfig.SessionSecret = os.Getenv("SESSION_SECRET")
+
+	var err error
+	logger, loggerCloser, err = logging.New(logging.Config{
+		Level:              os.Getenv("LOG_LEVEL"),
+		Production:         production,
+		EnclaveName:        GlobalConfig.EnclaveName,
+		GoogleCloudProject: os.Getenv("GOOGLE_CLOUD_PROJECT"),
+	})
+	if err != nil {
+		log.Fatalf("failed to initialize logger: %v", err)
+	}
+
+	if usersJSON := os.Getenv("AUTH_USERS"); usersJSON != "" {
+		if err := json.Unmarshal([]byte(usersJSON), &GlobalConfig.Users); err != nil {
+			logger.Error("AUTH_USERS is not valid JSON", "error", err)
+		}
+	}
+	if appPasswordsJSON := os.Getenv("APP_PASSWORDS"); appPasswordsJSON != "" {
+		if err := json.Unmarshal([]byte(appPasswordsJSON), &GlobalConfig.AppPasswords); err != nil {
+			logger.Error("APP_PASSWORDS is not valid JSON", "error", err)
+		}
+	}
 
 	portStr := os.Getenv("PORT")
 	if portStr != "" {
@@ -88,23 +120,27 @@ func loadConfig() {
 	// 2. Validate environment variables (matches Node.js exit logic)
 	var exitCode = 0
 	if GlobalConfig.EnclaveName == "" {
-		log.Println("ENCLAVE_NAME not set")
+		logger.Error("ENCLAVE_NAME not set")
 		exitCode = 1
 	}
 	if GlobalConfig.ScailoAPI == "" {
-		log.Println("SCAILO_API not set")
+		logger.Error("SCAILO_API not set")
 		exitCode = 1
 	}
 	if GlobalConfig.Port == 0 {
-		log.Println("PORT not set or is 0")
+		logger.Error("PORT not set or is 0")
 		exitCode = 1
 	}
 	if GlobalConfig.Username == "" {
-		log.Println("USERNAME not set")
+		logger.Error("USERNAME not set")
 		exitCode = 1
 	}
 	if GlobalConfig.Password == "" {
-		log.Println("PASSWORD not set")
+		logger.Error("PASSWORD not set")
+		exitCode = 1
+	}
+	if GlobalConfig.SessionSecret == "" {
+		logger.Error("SESSION_SECRET not set")
 		exitCode = 1
 	}
 
@@ -115,132 +151,67 @@ func loadConfig() {
 	}
 }
 
-// loginToAPI logs into the Scailo API
-func loginToAPI() {
-	// This function uses a goroutine to run asynchronously and recursively.
-
-	// Create a Ticker for the recurring job (1 hour)
-	ticker := time.NewTicker(loginInterval)
-
-	// Start the initial login immediately, then wait for the ticker.
-	performLogin()
-
-	// Wait for the ticker events in a separate goroutine
-	go func() {
-		for range ticker.C {
-			performLogin()
-		}
-	}()
-}
-
-func getServerURL() string {
-	if strings.HasPrefix(GlobalConfig.ScailoAPI, "http") || strings.Contains(GlobalConfig.ScailoAPI, "//") {
-		var split = strings.Split(GlobalConfig.ScailoAPI, "//")
-		if len(split) > 1 {
-			return split[1]
-		}
+// indexHandler is the single handler for all root/SPA routes. Asset
+// fingerprinting and caching are handled by assetPipeline; this just
+// serves whatever index.html it currently holds.
+func indexHandler(c *gin.Context) {
+	if err := siteAssets.refreshIfDev(); err != nil {
+		logging.FromContext(c.Request.Context()).Error("error loading index.html", "error", err)
+		c.String(http.StatusInternalServerError, "Index page not found.")
+		return
 	}
 
-	return GlobalConfig.ScailoAPI
+	c.Header("Content-Type", "text/html")
+	c.String(http.StatusOK, siteAssets.IndexHTML())
 }
 
-func performLogin() {
-	log.Println("About to login to API")
-
-	var creds grpc.DialOption
-	if strings.HasPrefix(GlobalConfig.ScailoAPI, "http://") {
-		// Without TLS
-		creds = grpc.WithTransportCredentials(insecure.NewCredentials())
-	} else {
-		// With TLS
-		creds = grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, getServerURL()))
+// main entry point
+func main() {
+	loadConfig()
+	defer loggerCloser()
+
+	var err error
+	authManager, err = auth.New(auth.Config{
+		SessionSecret: GlobalConfig.SessionSecret,
+		Users:         GlobalConfig.Users,
+		AppPasswords:  GlobalConfig.AppPasswords,
+		LoginPath:     fmt.Sprintf("%s/auth/login", enclavePrefix),
+		Secure:        production,
+	})
+	if err != nil {
+		log.Fatalf("failed to initialize auth manager: %v", err)
 	}
 
-	conn, err := grpc.NewClient(getServerURL(), creds)
+	tokenManager, err = scailoauth.New(scailoauth.Config{
+		ServerAddr:    GlobalConfig.ScailoAPI,
+		Username:      GlobalConfig.Username,
+		Password:      GlobalConfig.Password,
+		TokenLifetime: loginInterval,
+		Logger:        logger,
+	})
 	if err != nil {
-		log.Fatalf("did not connect: %v", err)
+		log.Fatalf("failed to initialize Scailo auth: %v", err)
 	}
-	defer conn.Close()
-
-	ctx := context.Background()
+	defer tokenManager.Close()
 
-	loginClient := sdk.NewLoginServiceClient(conn)
-	loginResp, err := loginClient.LoginAsEmployeePrimary(ctx, &sdk.UserLoginRequest{
-		Username:          GlobalConfig.Username,
-		PlainTextPassword: GlobalConfig.Password,
-	})
+	siteAssets, err = newAssetPipeline(production)
 	if err != nil {
-		panic(err)
+		log.Fatalf("failed to initialize asset pipeline: %v", err)
 	}
 
-	md := metadata.Pairs(
-		"auth_token", loginResp.AuthToken,
+	healthRegistry := health.NewRegistry(
+		health.NewLoginChecker(tokenManager, 2*loginInterval),
+		health.NewGRPCChecker(tokenManager.Conn()),
+		health.NewIndexHTMLChecker(siteAssets.refreshIfDev),
 	)
 
-	// 4. Create a new context with the metadata attached.
-	ScailoAPICtx = metadata.NewOutgoingContext(ctx, md)
-
-	mu.Lock()
-	AuthToken = loginResp.AuthToken
-	mu.Unlock()
-
-	log.Printf("Logged in with auth token: %s", AuthToken)
-}
-
-// replaceBundleCaches implements the cache-busting logic
-func replaceBundleCaches(page string) string {
-	version := time.Now().Format("20060102150405") // YYYYMMDDhhmmss format
-
-	// Replace script preload
-	page = IndexPageReplacer(page,
-		fmt.Sprintf(`<link rel="preload" as="script" href="%s/resources/dist/js/bundle.src.min.js">`, enclavePrefix),
-		fmt.Sprintf(`<link rel="preload" as="script" href="%s/resources/dist/js/bundle.src.min.js?v=%s">`, enclavePrefix, version))
-
-	// Replace script src
-	page = IndexPageReplacer(page,
-		fmt.Sprintf(`<script src="%s/resources/dist/js/bundle.src.min.js"></script>`, enclavePrefix),
-		fmt.Sprintf(`<script src="%s/resources/dist/js/bundle.src.min.js?v=%s"></script>`, enclavePrefix, version))
-
-	// Replace stylesheet link
-	page = IndexPageReplacer(page,
-		fmt.Sprintf(`<link rel="stylesheet" href="%s/resources/dist/css/bundle.css">`, enclavePrefix),
-		fmt.Sprintf(`<link rel="stylesheet" href="%s/resources/dist/css/bundle.css?v=%s">`, enclavePrefix, version))
-
-	return page
-}
-
-// IndexPageReplacer is a helper to centralize string replacement with logging.
-func IndexPageReplacer(s, old, new string) string {
-	return strings.ReplaceAll(s, old, new)
-}
-
-// indexHandler is the single handler for all root/SPA routes.
-func indexHandler(c *gin.Context) {
-	// 1. Read index.html logic
-	if !production || indexPage == "" {
-		content, err := os.ReadFile(indexHTMLFile)
-		if err != nil {
-			log.Printf("Error reading index.html: %v", err)
-			c.String(http.StatusInternalServerError, "Index page not found.")
-			return
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+	go func() {
+		if err := tokenManager.Run(ctx); err != nil && err != context.Canceled {
+			logger.Error("Scailo login loop stopped", "error", err)
 		}
-		indexPage = string(content)
-	}
-
-	// 2. Cache busting logic
-	pageWithCache := replaceBundleCaches(indexPage)
-
-	// 3. Set headers and send response
-	c.Header("Content-Type", "text/html")
-	c.String(http.StatusOK, pageWithCache)
-}
-
-// main entry point
-func main() {
-	loadConfig()
-
-	// Start the recurring login process asynchronously
-	go loginToAPI()
+	}()
 
 	// Set Gin to release mode if in production
 	if production {
@@ -249,36 +220,60 @@ func main() {
 
 	// Initialize Gin
 	router := gin.Default()
+	router.Use(logging.Middleware(logger))
+	router.Use(metrics.Middleware())
 
 	// --- 1. Register Static Routes ---
-	router.Static(fmt.Sprintf("%s/resources/dist", enclavePrefix), filepath.Join("resources", "dist"))
+	router.GET(fmt.Sprintf("%s/resources/dist/*filepath", enclavePrefix), gin.WrapF(siteAssets.handler()))
+
+	// --- Auth Routes ---
+	// GET serves the SPA itself (unauthenticated) so its client-side
+	// router can render a login form; the form POSTs credentials to the
+	// same path. Without this, Middleware's redirect to loginPath would
+	// fall through to NoRoute, which bounces back to /ui, looping forever.
+	router.GET(fmt.Sprintf("%s/auth/login", enclavePrefix), indexHandler)
+	router.POST(fmt.Sprintf("%s/auth/login", enclavePrefix), authManager.LoginHandler)
+	router.POST(fmt.Sprintf("%s/auth/logout", enclavePrefix), authManager.LogoutHandler)
 
 	// --- 2. Health Checks ---
-	router.GET(fmt.Sprintf("%s/health/startup", enclavePrefix), func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "OK"})
-	})
-	router.GET(fmt.Sprintf("%s/health/liveliness", enclavePrefix), func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "OK"})
-	})
-	router.GET(fmt.Sprintf("%s/health/readiness", enclavePrefix), func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "OK"})
-	})
+	router.GET(fmt.Sprintf("%s/health/startup", enclavePrefix), healthRegistry.StartupHandler)
+	router.GET(fmt.Sprintf("%s/health/liveliness", enclavePrefix), health.LivelinessHandler)
+	router.GET(fmt.Sprintf("%s/health/readiness", enclavePrefix), healthRegistry.ReadinessHandler)
+
+	// --- Metrics ---
+	// Gated by METRICS_TOKEN when set (for scrapers that can't go through
+	// the interactive auth flow), otherwise by the regular auth middleware.
+	metricsAuth := authManager.Middleware()
+	if token := os.Getenv("METRICS_TOKEN"); token != "" {
+		metricsAuth = metrics.RequireBearerToken(token)
+	}
+	router.GET(fmt.Sprintf("%s/metrics", enclavePrefix), metricsAuth, gin.WrapH(metrics.Handler()))
 
-	// --- 3. API Endpoint ---
+	// --- 3. API Endpoints ---
 	// Using a parameter for enclaveName so it matches the route pattern exactly
-	router.GET(fmt.Sprintf("%s/api/random", enclavePrefix), func(c *gin.Context) {
+	router.GET(fmt.Sprintf("%s/api/random", enclavePrefix), authManager.Middleware(), func(c *gin.Context) {
 		// Generate a random float between 0.0 and 1.0 (like Math.random())
 		randomNumber := rand.Float64()
 		c.JSON(http.StatusOK, gin.H{"random": randomNumber})
 	})
 
+	// Hand-rolled HTTP/JSON bridge onto the Scailo gRPC surface. Routes
+	// are registered here, one per exposed RPC, as the enclave starts
+	// calling real Scailo SDK services from the SPA. Add a
+	// gatewayRegistry.RegisterXService(tokenManager.Conn()) call per
+	// additional sdk.*ServiceClient the enclave needs to expose.
+	gatewayRegistry := gateway.NewRegistry(tokenManager)
+	gatewayRegistry.RegisterUsersService(tokenManager.Conn())
+	apiV1 := router.Group(fmt.Sprintf("%s/api/v1", enclavePrefix), authManager.Middleware())
+	gatewayRegistry.Mount(apiV1)
+
 	// --- 4. Index Page / SPA Routes (all pointing to the same handler) ---
 	// Specific UI routes
 	uiPath1 := fmt.Sprintf("%s/ui", enclavePrefix)
 	uiPath2 := fmt.Sprintf("%s/ui/*path", enclavePrefix)
 
-	router.GET(uiPath1, indexHandler)
-	router.GET(uiPath2, indexHandler)
+	router.GET(uiPath1, authManager.Middleware(), indexHandler)
+	router.GET(uiPath2, authManager.Middleware(), indexHandler)
 
 	// --- 5. Not Found Handler ---
 	router.NoRoute(func(c *gin.Context) {
@@ -288,9 +283,34 @@ func main() {
 
 	// --- 6. Start Server ---
 	address := fmt.Sprintf("0.0.0.0:%d", GlobalConfig.Port)
-	log.Printf("Listening on address %s with Production: %t", address, production)
+	httpServer := &http.Server{
+		Addr:    address,
+		Handler: router,
+	}
 
-	if err := router.Run(address); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("listening", "address", address, "production", production)
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			serveErr <- err
+			return
+		}
+		serveErr <- nil
+	}()
+
+	select {
+	case err := <-serveErr:
+		if err != nil {
+			logger.Error("server failed to start", "error", err)
+			os.Exit(1)
+		}
+	case <-ctx.Done():
+		logger.Info("shutting down", "signal", ctx.Err())
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer shutdownCancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("server shutdown failed", "error", err)
+		}
+		<-serveErr
 	}
 }